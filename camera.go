@@ -103,3 +103,51 @@ func (c *Camera) HandleKeyboard(window *glfw.Window, deltaTime float64) {
 		c.Position = c.Position.Add(c.Right.Mul(cameraSpeed))
 	}
 }
+
+// gamepadDeadzone is the magnitude below which a stick axis is treated as
+// centered, to absorb analog stick drift.
+const gamepadDeadzone = 0.15
+
+// applyDeadzone zeroes out axis values whose magnitude falls within dz.
+func applyDeadzone(v, dz float32) float32 {
+	if v > -dz && v < dz {
+		return 0
+	}
+	return v
+}
+
+// HandleGamepad processes gamepad input for camera movement and look, using
+// the left stick for strafe/forward, the right stick for yaw/pitch, and the
+// triggers for vertical movement along WorldUp. It is a no-op (falling back
+// silently to keyboard/mouse) when jid is not a connected gamepad.
+func (c *Camera) HandleGamepad(jid glfw.Joystick, deltaTime float64) {
+	state := jid.GetGamepadState()
+	if state == nil {
+		return
+	}
+
+	speed := float32(deltaTime) * c.MovementSpeed
+
+	strafe := applyDeadzone(state.Axes[glfw.AxisLeftX], gamepadDeadzone)
+	forward := applyDeadzone(-state.Axes[glfw.AxisLeftY], gamepadDeadzone)
+	c.Position = c.Position.Add(c.Front.Mul(forward * speed))
+	c.Position = c.Position.Add(c.Right.Mul(strafe * speed))
+
+	lookSpeed := c.MouseSens * 100
+	c.Yaw += applyDeadzone(state.Axes[glfw.AxisRightX], gamepadDeadzone) * lookSpeed * float32(deltaTime)
+	c.Pitch += applyDeadzone(-state.Axes[glfw.AxisRightY], gamepadDeadzone) * lookSpeed * float32(deltaTime)
+
+	if c.Pitch > 89.0 {
+		c.Pitch = 89.0
+	}
+	if c.Pitch < -89.0 {
+		c.Pitch = -89.0
+	}
+
+	// Triggers report [-1, 1] at rest/fully pressed; normalize to [0, 1].
+	leftTrigger := (state.Axes[glfw.AxisLeftTrigger] + 1) / 2
+	rightTrigger := (state.Axes[glfw.AxisRightTrigger] + 1) / 2
+	c.Position = c.Position.Add(c.WorldUp.Mul((rightTrigger - leftTrigger) * speed))
+
+	c.updateCameraVectors()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// WindowedGeometry remembers a window's position and size while it is
+// fullscreen, so toggling back to windowed mode can restore it.
+type WindowedGeometry struct {
+	X, Y, W, H int
+}
+
+// toggleFullscreen recreates win against glfw.GetPrimaryMonitor()'s video
+// mode (or back to windowed, restoring windowed's last known geometry),
+// sharing the existing GL context so no resources need to be reloaded.
+// register must reattach window callbacks (and sync any size-dependent
+// state) to the returned window.
+func toggleFullscreen(win *glfw.Window, register func(*glfw.Window), windowed *WindowedGeometry) *glfw.Window {
+	if win.GetMonitor() == nil {
+		windowed.X, windowed.Y = win.GetPos()
+		windowed.W, windowed.H = win.GetSize()
+
+		monitor := glfw.GetPrimaryMonitor()
+		mode := monitor.GetVideoMode()
+		newWin, err := glfw.CreateWindow(mode.Width, mode.Height, "opengl go test", monitor, win)
+		if err != nil {
+			log.Fatal("failed to enter fullscreen:", err)
+		}
+
+		newWin.MakeContextCurrent()
+		register(newWin)
+		win.Destroy()
+		return newWin
+	}
+
+	newWin, err := glfw.CreateWindow(windowed.W, windowed.H, "opengl go test", nil, win)
+	if err != nil {
+		log.Fatal("failed to leave fullscreen:", err)
+	}
+
+	newWin.MakeContextCurrent()
+	newWin.SetPos(windowed.X, windowed.Y)
+	register(newWin)
+	win.Destroy()
+	return newWin
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// CheckGLError drains gl.GetError() and prints each pending error tagged
+// with tag, so a single call site reports every error raised since the
+// last check instead of only the first.
+func CheckGLError(tag string) {
+	for {
+		code := gl.GetError()
+		if code == gl.NO_ERROR {
+			return
+		}
+		fmt.Printf("[gl error] %s: %s\n", tag, glErrorString(code))
+	}
+}
+
+// glErrorString translates a gl.GetError() code into a human-readable name.
+func glErrorString(code uint32) string {
+	switch code {
+	case gl.INVALID_ENUM:
+		return "GL_INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		return "GL_INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		return "GL_INVALID_OPERATION"
+	case gl.OUT_OF_MEMORY:
+		return "GL_OUT_OF_MEMORY"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		return "GL_INVALID_FRAMEBUFFER_OPERATION"
+	default:
+		return fmt.Sprintf("GL_UNKNOWN_ERROR(0x%X)", code)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build gldebug
+
+package main
+
+// checkGLErrorIfDebug calls CheckGLError(tag) when built with -tags
+// gldebug, without paying the gl.GetError() round-trip in release builds.
+// It is only as good as the call sites that invoke it: it narrows a GL
+// error down to whichever instrumented checkpoint it was first observed
+// after, not to the exact gl.* call that raised it. Add a call after any
+// new GL entry point you want covered.
+func checkGLErrorIfDebug(tag string) {
+	CheckGLError(tag)
+}
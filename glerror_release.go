@@ -0,0 +1,6 @@
+//go:build !gldebug
+
+package main
+
+// checkGLErrorIfDebug is a no-op outside of -tags gldebug builds.
+func checkGLErrorIfDebug(tag string) {}
@@ -2,12 +2,8 @@ package main
 
 import (
 	"fmt"
-	"image"
-	"image/jpeg"
 	"log"
-	"os"
 	"runtime"
-	"strings"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -15,95 +11,6 @@ import (
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-func createShader(source string, shaderType uint32) uint32 {
-	shader := gl.CreateShader(shaderType)
-	csources, free := gl.Strs(source)
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-		fmt.Printf("Failed to compile shader: %v\n", log)
-	}
-
-	return shader
-}
-
-func createProgram(vertexShaderSource, fragmentShaderSource string) uint32 {
-	vertexShader := createShader(vertexShaderSource, gl.VERTEX_SHADER)
-	fragmentShader := createShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
-
-	program := gl.CreateProgram()
-	gl.AttachShader(program, vertexShader)
-	gl.AttachShader(program, fragmentShader)
-	gl.LinkProgram(program)
-
-	var status int32
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-		fmt.Printf("Failed to link program: %v\n", log)
-	}
-
-	gl.DeleteShader(vertexShader)
-	gl.DeleteShader(fragmentShader)
-
-	return program
-}
-
-func loadTexture(filename string) uint32 {
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("failed to open texture file: %v", err)
-	}
-	defer file.Close()
-
-	img, err := jpeg.Decode(file)
-	if err != nil {
-		log.Fatalf("failed to decode JPEG: %v", err)
-	}
-
-	rgba := image.NewRGBA(img.Bounds())
-	for y := 0; y < rgba.Bounds().Dy(); y++ {
-		for x := 0; x < rgba.Bounds().Dx(); x++ {
-			rgba.Set(x, y, img.At(x, y))
-		}
-	}
-
-	var texture uint32
-	gl.GenTextures(1, &texture)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-
-	gl.TexImage2D(
-		gl.TEXTURE_2D,
-		0,
-		gl.RGBA,
-		int32(rgba.Bounds().Dx()),
-		int32(rgba.Bounds().Dy()),
-		0,
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		gl.Ptr(rgba.Pix))
-
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-
-	return texture
-}
-
 type FPSCounter struct {
 	lastUpdate float64
 	frameCount int
@@ -173,68 +80,91 @@ func main() {
 	camera := NewCamera()
 	camera.Position = mgl32.Vec3{0, 0, 15} // Move camera back to see the grid
 
-	// Capture cursor
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	fbWidth, fbHeight := window.GetFramebufferSize()
+	renderer := NewRenderer(camera, int32(fbWidth), int32(fbHeight))
+
+	// register (re)attaches every window-level callback and syncs
+	// size-dependent state. It is called once at startup and again on every
+	// fullscreen toggle, since recreating the window drops its callbacks.
+	register := func(w *glfw.Window) {
+		w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+
+		w.SetFramebufferSizeCallback(func(w *glfw.Window, width int, height int) {
+			renderer.OnFramebufferSize(int32(width), int32(height))
+		})
+		w.SetCursorPosCallback(func(w *glfw.Window, xpos float64, ypos float64) {
+			if camera.firstMouse {
+				camera.lastX = xpos
+				camera.lastY = ypos
+				camera.firstMouse = false
+			}
 
-	// Set callbacks
-	window.SetCursorPosCallback(func(w *glfw.Window, xpos float64, ypos float64) {
-		if camera.firstMouse {
+			xoffset := xpos - camera.lastX
+			yoffset := camera.lastY - ypos
 			camera.lastX = xpos
 			camera.lastY = ypos
-			camera.firstMouse = false
-		}
 
-		xoffset := xpos - camera.lastX
-		yoffset := camera.lastY - ypos
-		camera.lastX = xpos
-		camera.lastY = ypos
+			xoffset *= float64(camera.MouseSens)
+			yoffset *= float64(camera.MouseSens)
 
-		xoffset *= float64(camera.MouseSens)
-		yoffset *= float64(camera.MouseSens)
+			camera.Yaw += float32(xoffset)
+			camera.Pitch += float32(yoffset)
 
-		camera.Yaw += float32(xoffset)
-		camera.Pitch += float32(yoffset)
+			if camera.Pitch > 89.0 {
+				camera.Pitch = 89.0
+			}
+			if camera.Pitch < -89.0 {
+				camera.Pitch = -89.0
+			}
 
-		if camera.Pitch > 89.0 {
-			camera.Pitch = 89.0
-		}
-		if camera.Pitch < -89.0 {
-			camera.Pitch = -89.0
-		}
+			camera.updateCameraVectors()
+		})
 
-		camera.updateCameraVectors()
-	})
+		fbWidth, fbHeight := w.GetFramebufferSize()
+		renderer.OnFramebufferSize(int32(fbWidth), int32(fbHeight))
+	}
+	register(window)
 
-	vertexShaderSource := `
-		#version 410
-		layout(location = 0) in vec3 position;
-		layout(location = 1) in vec2 texCoords;
-		out vec2 TexCoords;
-		uniform mat4 model;
-		uniform mat4 view;
-		uniform mat4 projection;
-		void main() {
-			gl_Position = projection * view * model * vec4(position, 1.0);
-			TexCoords = texCoords;
+	windowedGeometry := WindowedGeometry{W: 800, H: 600}
+	windowedGeometry.X, windowedGeometry.Y = window.GetPos()
+
+	// Track the active gamepad, hot-plugging on connect/disconnect.
+	activeGamepad := glfw.Joystick(-1)
+	for jid := glfw.Joystick1; jid <= glfw.JoystickLast; jid++ {
+		if jid.Present() && jid.IsGamepad() {
+			activeGamepad = jid
+			break
 		}
-	` + "\x00"
-
-	fragmentShaderSource := `
-		#version 410
-		in vec2 TexCoords;
-		out vec4 color;
-		uniform sampler2D texture1;
-		void main() {
-			color = texture(texture1, TexCoords);
+	}
+	glfw.SetJoystickCallback(func(joy glfw.Joystick, event glfw.PeripheralEvent) {
+		switch event {
+		case glfw.Connected:
+			if activeGamepad < 0 && joy.IsGamepad() {
+				activeGamepad = joy
+			}
+		case glfw.Disconnected:
+			if joy == activeGamepad {
+				activeGamepad = -1
+				for jid := glfw.Joystick1; jid <= glfw.JoystickLast; jid++ {
+					if jid.Present() && jid.IsGamepad() {
+						activeGamepad = jid
+						break
+					}
+				}
+			}
 		}
-	` + "\x00"
-
-	program := createProgram(vertexShaderSource, fragmentShaderSource)
+	})
 
-	// Get uniform locations
-	modelLoc := gl.GetUniformLocation(program, gl.Str("model\x00"))
-	viewLoc := gl.GetUniformLocation(program, gl.Str("view\x00"))
-	projLoc := gl.GetUniformLocation(program, gl.Str("projection\x00"))
+	shaderManager := NewShaderManager("shaders")
+	gridProgram, err := shaderManager.Load("grid")
+	if err != nil {
+		log.Fatal(err)
+	}
+	program := gridProgram.Handle
+	modelLoc := gridProgram.Uniform("model")
+	viewLoc := gridProgram.Uniform("view")
+	projLoc := gridProgram.Uniform("projection")
+	checkGLErrorIfDebug("load grid shader")
 
 	// Define vertices for a quad (made smaller to accommodate gaps)
 	quadSize := float32(0.8) // Slightly smaller than 1.0 to create gaps
@@ -260,9 +190,11 @@ func main() {
 
 	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
 	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+	checkGLErrorIfDebug("upload grid vertex buffer")
 
 	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
 	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	checkGLErrorIfDebug("upload grid index buffer")
 
 	stride := int32(5 * unsafe.Sizeof(float32(0)))
 	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, nil)
@@ -272,13 +204,24 @@ func main() {
 	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, stride, texOffset)
 	gl.EnableVertexAttribArray(1)
 
-	texture := loadTexture("test_grass.jpg")
+	texOpts := DefaultTextureOptions()
+	texOpts.GenerateMipmaps = true
+	tex, err := LoadTexture("test_grass.jpg", texOpts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fountain of grass blades spawning above the grid
+	grassFountain := NewParticleSystem(500, 3.0, tex)
+	grassFountain.Emit(mgl32.Vec3{0, 6, 0}, mgl32.Vec3{0, 4, 0})
 
 	// Timing variables
 	var deltaTime float64
 	var lastFrame float64
 
 	fpsCounter := NewFPSCounter()
+	fullscreenTogglePressed := false
+	reloadKeyPressed := false
 
 	// Main render loop
 	for !window.ShouldClose() {
@@ -293,53 +236,88 @@ func main() {
 		fps := fpsCounter.Update()
 		window.SetTitle(fmt.Sprintf("10x10 Grid Example | FPS: %.1f", fps))
 
-		// Camera movement
-		cameraSpeed := float32(deltaTime) * camera.MovementSpeed
-		if window.GetKey(glfw.KeyW) == glfw.Press {
-			camera.Position = camera.Position.Add(camera.Front.Mul(cameraSpeed))
+		// F11 or Alt+Enter toggles fullscreen, edge-triggered so holding the
+		// key doesn't toggle every frame.
+		altEnter := (window.GetKey(glfw.KeyLeftAlt) == glfw.Press || window.GetKey(glfw.KeyRightAlt) == glfw.Press) &&
+			window.GetKey(glfw.KeyEnter) == glfw.Press
+		togglePressed := window.GetKey(glfw.KeyF11) == glfw.Press || altEnter
+		if togglePressed && !fullscreenTogglePressed {
+			window = toggleFullscreen(window, register, &windowedGeometry)
 		}
-		if window.GetKey(glfw.KeyS) == glfw.Press {
-			camera.Position = camera.Position.Sub(camera.Front.Mul(cameraSpeed))
-		}
-		if window.GetKey(glfw.KeyA) == glfw.Press {
-			camera.Position = camera.Position.Sub(camera.Right.Mul(cameraSpeed))
+		fullscreenTogglePressed = togglePressed
+
+		// R reloads the grid shader from disk, edge-triggered like the
+		// fullscreen toggle. A failed reload keeps the program that's
+		// already live.
+		reloadPressed := window.GetKey(glfw.KeyR) == glfw.Press
+		if reloadPressed && !reloadKeyPressed {
+			if err := shaderManager.Reload("grid"); err != nil {
+				fmt.Printf("shader reload failed: %v\n", err)
+			} else {
+				gridProgram = shaderManager.Program("grid")
+				program = gridProgram.Handle
+				modelLoc = gridProgram.Uniform("model")
+				viewLoc = gridProgram.Uniform("view")
+				projLoc = gridProgram.Uniform("projection")
+			}
 		}
-		if window.GetKey(glfw.KeyD) == glfw.Press {
-			camera.Position = camera.Position.Add(camera.Right.Mul(cameraSpeed))
+		reloadKeyPressed = reloadPressed
+
+		// Camera movement: prefer the active gamepad, fall back to keyboard.
+		if activeGamepad >= 0 {
+			camera.HandleGamepad(activeGamepad, deltaTime)
+		} else {
+			camera.HandleKeyboard(window, deltaTime)
 		}
 
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-		gl.UseProgram(program)
 
-		// Set up view and projection matrices
-		projection := mgl32.Perspective(mgl32.DegToRad(45.0), 800.0/600.0, 0.1, 100.0)
-		view := camera.GetViewMatrix()
+		grassFountain.Step(float32(deltaTime))
 
-		gl.UniformMatrix4fv(projLoc, 1, false, &projection[0])
-		gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
+		gl.UseProgram(program)
 
 		// Bind texture
-		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.BindTexture(gl.TEXTURE_2D, tex.ID)
 		gl.BindVertexArray(vao)
 
-		// Draw 10x10 grid of quads
-		for row := 0; row < 10; row++ {
-			for col := 0; col < 10; col++ {
-				// Calculate position with spacing
-				xPos := float32(col) - 4.5 // Center the grid (10-1)/2 = 4.5
-				yPos := float32(row) - 4.5
-
-				// Create model matrix for this quad
-				model := mgl32.Ident4()
-				model = model.Mul4(mgl32.Translate3D(xPos, yPos, 0))
-
-				// Send model matrix to shader
-				gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
-
-				// Draw the quad
-				gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+		renderer.Render(func(cam *Camera, projection mgl32.Mat4) {
+			view := cam.GetViewMatrix()
+			gl.UniformMatrix4fv(projLoc, 1, false, &projection[0])
+			gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
+
+			// Draw 10x10 grid of quads
+			transforms := NewTransformStack()
+			for row := 0; row < 10; row++ {
+				yPos := float32(row) - 4.5 // Center the grid (10-1)/2 = 4.5
+				transforms.Push(mgl32.Translate3D(0, yPos, 0))
+
+				for col := 0; col < 10; col++ {
+					xPos := float32(col) - 4.5
+					transforms.Push(mgl32.Translate3D(xPos, 0, 0))
+
+					// Send the accumulated model matrix to the shader
+					model := transforms.Peek()
+					gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
+
+					// Draw the quad
+					gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+					checkGLErrorIfDebug("draw grid quad")
+
+					if _, err := transforms.Pop(); err != nil {
+						log.Fatalf("grid render: %v", err)
+					}
+				}
+
+				if _, err := transforms.Pop(); err != nil {
+					log.Fatalf("grid render: %v", err)
+				}
 			}
-		}
+
+			grassFountain.Draw(view, projection)
+			gl.UseProgram(program)
+			gl.BindTexture(gl.TEXTURE_2D, tex.ID)
+			gl.BindVertexArray(vao)
+		})
 
 		window.SwapBuffers()
 		glfw.PollEvents()
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// particleVertexSize is the stride, in float32s, of one particle's
+// {Position vec3; Velocity vec3; Age float; Life float} record.
+const particleVertexSize = 3 + 3 + 1 + 1
+
+const particleSimVertexSource = `
+	#version 410
+	layout(location = 0) in vec3 iPosition;
+	layout(location = 1) in vec3 iVelocity;
+	layout(location = 2) in float iAge;
+	layout(location = 3) in float iLife;
+
+	out vec3 oPosition;
+	out vec3 oVelocity;
+	out float oAge;
+	out float oLife;
+
+	uniform vec3 origin;
+	uniform vec3 dir;
+	uniform vec3 gravity;
+	uniform float dt;
+
+	void main() {
+		float age = iAge + dt;
+		vec3 pos = iPosition;
+		vec3 vel = iVelocity;
+
+		if (age > iLife) {
+			// Spread respawn velocity per-particle using gl_VertexID as a
+			// deterministic pseudo-random seed, since the CPU never
+			// touches this buffer again after init.
+			float id = float(gl_VertexID);
+			float angle = fract(sin(id * 12.9898) * 43758.5453) * 6.283185;
+			float radius = fract(sin(id * 78.233) * 43758.5453) * 0.5;
+			vec3 spread = vec3(cos(angle), 0.0, sin(angle)) * radius;
+
+			pos = origin;
+			vel = dir + spread;
+			age = 0.0;
+		} else {
+			vel += gravity * dt;
+			pos += vel * dt;
+		}
+
+		oPosition = pos;
+		oVelocity = vel;
+		oAge = age;
+		oLife = iLife;
+
+		gl_Position = vec4(pos, 1.0);
+	}
+` + "\x00"
+
+// The fragment shader is never executed (GL_RASTERIZER_DISCARD is enabled
+// during the simulation pass) but core profile programs still require one
+// to link.
+const particleSimFragmentSource = `
+	#version 410
+	void main() {}
+` + "\x00"
+
+const particleRenderVertexSource = `
+	#version 410
+	layout(location = 0) in vec3 iPosition;
+	layout(location = 2) in float iAge;
+	layout(location = 3) in float iLife;
+
+	out float vAlpha;
+
+	uniform mat4 view;
+	uniform mat4 projection;
+
+	void main() {
+		gl_Position = projection * view * vec4(iPosition, 1.0);
+		gl_PointSize = 8.0;
+		vAlpha = 1.0 - (iAge / iLife);
+	}
+` + "\x00"
+
+const particleRenderFragmentSource = `
+	#version 410
+	in float vAlpha;
+	out vec4 color;
+	uniform sampler2D tex;
+
+	void main() {
+		vec4 texColor = texture(tex, gl_PointCoord);
+		color = vec4(texColor.rgb, texColor.a * vAlpha);
+	}
+` + "\x00"
+
+// ParticleSystem simulates particles entirely on the GPU via transform
+// feedback: the CPU uploads the initial buffer once and never touches
+// per-particle data again. Each Step ping-pongs between two vertex buffers,
+// reading the previous state and writing the integrated state via transform
+// feedback; Draw renders the current state as alpha-blended point sprites.
+type ParticleSystem struct {
+	count int
+
+	buffers [2]uint32
+	vaos    [2]uint32
+	read    int // index into buffers/vaos currently holding live data
+
+	simProgram    uint32
+	renderProgram uint32
+
+	simUniforms    map[string]int32
+	renderUniforms map[string]int32
+
+	texture *Texture
+
+	origin  mgl32.Vec3
+	dir     mgl32.Vec3
+	Gravity mgl32.Vec3
+}
+
+// NewParticleSystem allocates a ping-pong pair of buffers for count
+// particles, starting at the zero position and velocity with ages staggered
+// across [0, life), so each one first respawns from the emitter set by Emit
+// once its age passes life.
+func NewParticleSystem(count int, life float32, texture *Texture) *ParticleSystem {
+	ps := &ParticleSystem{
+		count:   count,
+		texture: texture,
+		Gravity: mgl32.Vec3{0, -9.8, 0},
+	}
+
+	initial := make([]float32, count*particleVertexSize)
+	for i := 0; i < count; i++ {
+		base := i * particleVertexSize
+		// Position and velocity default to zero. Age is staggered across
+		// [0, life) per particle so they cross their Age > Life respawn
+		// threshold at different times instead of in lockstep — the sim
+		// shader then spreads each respawn's velocity via gl_VertexID,
+		// together giving each particle its own phase and drift forever
+		// after, since the CPU never touches this buffer again.
+		initial[base+6] = float32(i) / float32(count) * life
+		initial[base+7] = life
+	}
+
+	gl.GenBuffers(2, &ps.buffers[0])
+	gl.GenVertexArrays(2, &ps.vaos[0])
+	for i := 0; i < 2; i++ {
+		gl.BindVertexArray(ps.vaos[i])
+		gl.BindBuffer(gl.ARRAY_BUFFER, ps.buffers[i])
+		gl.BufferData(gl.ARRAY_BUFFER, len(initial)*4, gl.Ptr(initial), gl.STREAM_COPY)
+		checkGLErrorIfDebug("upload particle buffer")
+
+		stride := int32(particleVertexSize * 4)
+		gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(0))
+		gl.EnableVertexAttribArray(0)
+		gl.VertexAttribPointer(1, 3, gl.FLOAT, false, stride, gl.PtrOffset(3*4))
+		gl.EnableVertexAttribArray(1)
+		gl.VertexAttribPointer(2, 1, gl.FLOAT, false, stride, gl.PtrOffset(6*4))
+		gl.EnableVertexAttribArray(2)
+		gl.VertexAttribPointer(3, 1, gl.FLOAT, false, stride, gl.PtrOffset(7*4))
+		gl.EnableVertexAttribArray(3)
+	}
+
+	ps.simProgram = createTransformFeedbackProgram(
+		particleSimVertexSource, particleSimFragmentSource,
+		[]string{"oPosition", "oVelocity", "oAge", "oLife"})
+
+	renderProgram, err := linkProgram(particleRenderVertexSource, particleRenderFragmentSource)
+	if err != nil {
+		log.Fatalf("failed to link particle render program: %v", err)
+	}
+	ps.renderProgram = renderProgram
+
+	ps.simUniforms = fetchUniforms(ps.simProgram, "origin", "dir", "gravity", "dt")
+	ps.renderUniforms = fetchUniforms(ps.renderProgram, "view", "projection", "tex")
+
+	return ps
+}
+
+// createTransformFeedbackProgram links a program whose vertex shader
+// outputs are captured into a transform feedback buffer instead of (or in
+// addition to) being rasterized. Transform feedback varyings must be
+// declared before linking, so this can't go through linkProgram and
+// instead compiles via compileShader and links by hand.
+func createTransformFeedbackProgram(vertexSource, fragmentSource string, varyings []string) uint32 {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		log.Fatalf("failed to compile transform feedback vertex shader: %v", err)
+	}
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		log.Fatalf("failed to compile transform feedback fragment shader: %v", err)
+	}
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+
+	cstrs, free := gl.Strs(varyings...)
+	gl.TransformFeedbackVaryings(program, int32(len(varyings)), cstrs, gl.INTERLEAVED_ATTRIBS)
+	free()
+	checkGLErrorIfDebug("set transform feedback varyings")
+
+	gl.LinkProgram(program)
+	checkGLErrorIfDebug("link transform feedback program")
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+		infoLog := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(infoLog))
+		log.Fatalf("failed to link transform feedback program: %s", infoLog)
+	}
+
+	gl.DeleteShader(vertexShader)
+	gl.DeleteShader(fragmentShader)
+
+	return program
+}
+
+// fetchUniforms pre-fetches uniform locations for a program so the render
+// loop can look them up by name without a GL call per frame.
+func fetchUniforms(program uint32, names ...string) map[string]int32 {
+	locs := make(map[string]int32, len(names))
+	for _, name := range names {
+		locs[name] = gl.GetUniformLocation(program, gl.Str(name+"\x00"))
+	}
+	return locs
+}
+
+// Emit updates the emitter origin/direction used to respawn particles whose
+// age exceeds their life on the next Step.
+func (ps *ParticleSystem) Emit(origin, dir mgl32.Vec3) {
+	ps.origin = origin
+	ps.dir = dir
+}
+
+// Step advances the simulation by dt using transform feedback: it reads the
+// live buffer, writes the integrated state into the other buffer, then
+// swaps which buffer is considered live.
+func (ps *ParticleSystem) Step(dt float32) {
+	write := 1 - ps.read
+
+	gl.Enable(gl.RASTERIZER_DISCARD)
+	gl.UseProgram(ps.simProgram)
+
+	gl.Uniform3f(ps.simUniforms["origin"], ps.origin.X(), ps.origin.Y(), ps.origin.Z())
+	gl.Uniform3f(ps.simUniforms["dir"], ps.dir.X(), ps.dir.Y(), ps.dir.Z())
+	gl.Uniform3f(ps.simUniforms["gravity"], ps.Gravity.X(), ps.Gravity.Y(), ps.Gravity.Z())
+	gl.Uniform1f(ps.simUniforms["dt"], dt)
+
+	gl.BindVertexArray(ps.vaos[ps.read])
+	gl.BindBufferBase(gl.TRANSFORM_FEEDBACK_BUFFER, 0, ps.buffers[write])
+
+	gl.BeginTransformFeedback(gl.POINTS)
+	gl.DrawArrays(gl.POINTS, 0, int32(ps.count))
+	gl.EndTransformFeedback()
+	checkGLErrorIfDebug("particle system step")
+
+	gl.Disable(gl.RASTERIZER_DISCARD)
+
+	ps.read = write
+}
+
+// Draw renders the live particle buffer as alpha-blended, textured point
+// sprites.
+func (ps *ParticleSystem) Draw(view, projection mgl32.Mat4) {
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE)
+	gl.Enable(gl.PROGRAM_POINT_SIZE)
+
+	gl.UseProgram(ps.renderProgram)
+	gl.UniformMatrix4fv(ps.renderUniforms["view"], 1, false, &view[0])
+	gl.UniformMatrix4fv(ps.renderUniforms["projection"], 1, false, &projection[0])
+
+	if ps.texture != nil {
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.BindTexture(gl.TEXTURE_2D, ps.texture.ID)
+		gl.Uniform1i(ps.renderUniforms["tex"], 0)
+	}
+
+	gl.BindVertexArray(ps.vaos[ps.read])
+	gl.DrawArrays(gl.POINTS, 0, int32(ps.count))
+	checkGLErrorIfDebug("particle system draw")
+
+	gl.Disable(gl.PROGRAM_POINT_SIZE)
+	gl.Disable(gl.BLEND)
+}
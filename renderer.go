@@ -0,0 +1,102 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Viewport is a rectangular region of the framebuffer to render into.
+type Viewport struct {
+	X, Y, W, H int32
+}
+
+// Activate makes the viewport the target of subsequent GL draw calls.
+func (v Viewport) Activate() {
+	gl.Viewport(v.X, v.Y, v.W, v.H)
+}
+
+// CameraViewport pairs a camera with the viewport it is drawn into.
+type CameraViewport struct {
+	Camera   *Camera
+	Viewport Viewport
+}
+
+// Renderer owns one or more (Camera, Viewport) pairs and renders the scene
+// once per pair. It caches one perspective projection matrix per viewport,
+// rebuilding them only when the framebuffer is resized or the split changes.
+type Renderer struct {
+	targets     []CameraViewport
+	projections []mgl32.Mat4
+	dirty       bool
+
+	fbWidth, fbHeight int32
+
+	Fov, Near, Far float32
+}
+
+// NewRenderer creates a single full-screen viewport rendering through
+// camera, sized to fbWidth x fbHeight.
+func NewRenderer(camera *Camera, fbWidth, fbHeight int32) *Renderer {
+	r := &Renderer{
+		Fov:  45.0,
+		Near: 0.1,
+		Far:  100.0,
+	}
+	r.fbWidth, r.fbHeight = fbWidth, fbHeight
+	r.targets = []CameraViewport{{Camera: camera, Viewport: Viewport{0, 0, fbWidth, fbHeight}}}
+	r.dirty = true
+	return r
+}
+
+// SplitHorizontal replaces the current targets with two half-width
+// viewports side-by-side, e.g. for a player camera and a debug flycam.
+func (r *Renderer) SplitHorizontal(camLeft, camRight *Camera) {
+	halfW := r.fbWidth / 2
+	r.targets = []CameraViewport{
+		{Camera: camLeft, Viewport: Viewport{0, 0, halfW, r.fbHeight}},
+		{Camera: camRight, Viewport: Viewport{halfW, 0, r.fbWidth - halfW, r.fbHeight}},
+	}
+	r.dirty = true
+}
+
+// OnFramebufferSize updates the stored framebuffer size and recomputes the
+// current viewports (and, lazily, their cached projections) for the new
+// size. Wire this up via window.SetFramebufferSizeCallback.
+func (r *Renderer) OnFramebufferSize(width, height int32) {
+	r.fbWidth, r.fbHeight = width, height
+
+	switch len(r.targets) {
+	case 2:
+		r.SplitHorizontal(r.targets[0].Camera, r.targets[1].Camera)
+	default:
+		for i := range r.targets {
+			r.targets[i].Viewport = Viewport{0, 0, width, height}
+		}
+		r.dirty = true
+	}
+}
+
+// ensureProjections rebuilds the cached projection matrices if the
+// framebuffer size or viewport layout changed since the last render.
+func (r *Renderer) ensureProjections() {
+	if !r.dirty && len(r.projections) == len(r.targets) {
+		return
+	}
+
+	r.projections = make([]mgl32.Mat4, len(r.targets))
+	for i, t := range r.targets {
+		aspect := float32(t.Viewport.W) / float32(t.Viewport.H)
+		r.projections[i] = mgl32.Perspective(mgl32.DegToRad(r.Fov), aspect, r.Near, r.Far)
+	}
+	r.dirty = false
+}
+
+// Render activates each target's viewport in turn and invokes draw with its
+// camera and cached projection matrix.
+func (r *Renderer) Render(draw func(cam *Camera, projection mgl32.Mat4)) {
+	r.ensureProjections()
+	for i, t := range r.targets {
+		t.Viewport.Activate()
+		draw(t.Camera, r.projections[i])
+	}
+}
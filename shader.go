@@ -0,0 +1,244 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Program is a linked GL program with its active uniform locations
+// pre-fetched, so per-frame lookups are a map read instead of a GL call.
+type Program struct {
+	Handle   uint32
+	uniforms map[string]int32
+}
+
+// Uniform returns the cached location of name, or -1 if it is not an
+// active uniform of the program (e.g. optimized out by the compiler).
+func (p *Program) Uniform(name string) int32 {
+	if loc, ok := p.uniforms[name]; ok {
+		return loc
+	}
+	return -1
+}
+
+// ShaderManager loads named vertex/fragment pairs from disk, caching linked
+// Programs keyed by name, and supports reloading them from disk without
+// dropping the live program on a compile or link failure.
+type ShaderManager struct {
+	dir      string
+	programs map[string]*Program
+	hashes   map[string]string
+}
+
+// NewShaderManager returns a manager that loads "<name>.vert"/"<name>.frag"
+// pairs out of dir.
+func NewShaderManager(dir string) *ShaderManager {
+	return &ShaderManager{
+		dir:      dir,
+		programs: make(map[string]*Program),
+		hashes:   make(map[string]string),
+	}
+}
+
+// Program returns the currently cached program for name, or nil if it has
+// never been loaded.
+func (sm *ShaderManager) Program(name string) *Program {
+	return sm.programs[name]
+}
+
+// Load returns the cached program for name, compiling and linking it from
+// disk the first time it is requested.
+func (sm *ShaderManager) Load(name string) (*Program, error) {
+	if prog, ok := sm.programs[name]; ok {
+		return prog, nil
+	}
+
+	vertSrc, fragSrc, err := sm.readSources(name)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := linkProgram(string(vertSrc)+"\x00", string(fragSrc)+"\x00")
+	if err != nil {
+		return nil, fmt.Errorf("shader manager: compile %q: %w", name, err)
+	}
+
+	prog := &Program{Handle: handle, uniforms: fetchActiveUniforms(handle)}
+	sm.programs[name] = prog
+	sm.hashes[name] = sourceHash(vertSrc, fragSrc)
+	return prog, nil
+}
+
+// Reload re-reads name's source files and, only if they compile and link
+// successfully, atomically swaps the cached program for the new one and
+// deletes the old GL program. A failing reload leaves the previous program
+// live and returns the error.
+func (sm *ShaderManager) Reload(name string) error {
+	vertSrc, fragSrc, err := sm.readSources(name)
+	if err != nil {
+		return err
+	}
+
+	hash := sourceHash(vertSrc, fragSrc)
+	if hash == sm.hashes[name] {
+		return nil
+	}
+
+	handle, err := linkProgram(string(vertSrc)+"\x00", string(fragSrc)+"\x00")
+	if err != nil {
+		return fmt.Errorf("shader manager: reload %q: %w", name, err)
+	}
+
+	old := sm.programs[name]
+	sm.programs[name] = &Program{Handle: handle, uniforms: fetchActiveUniforms(handle)}
+	sm.hashes[name] = hash
+	if old != nil {
+		gl.DeleteProgram(old.Handle)
+	}
+	return nil
+}
+
+// Watch starts a background fsnotify watch on name's source files and calls
+// Reload whenever either is written, printing (rather than propagating) a
+// failed reload so a bad edit doesn't take down the running program. The
+// caller is responsible for closing the returned watcher.
+func (sm *ShaderManager) Watch(name string) (*fsnotify.Watcher, error) {
+	vertPath, fragPath := sm.sourcePaths(name)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("shader manager: watch %q: %w", name, err)
+	}
+	if err := watcher.Add(vertPath); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("shader manager: watch %q: %w", name, err)
+	}
+	if err := watcher.Add(fragPath); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("shader manager: watch %q: %w", name, err)
+	}
+
+	go func() {
+		for event := range watcher.Events {
+			if event.Op&fsnotify.Write == 0 {
+				continue
+			}
+			if err := sm.Reload(name); err != nil {
+				fmt.Printf("shader manager: reload %q failed: %v\n", name, err)
+			}
+		}
+	}()
+
+	return watcher, nil
+}
+
+func (sm *ShaderManager) sourcePaths(name string) (vertPath, fragPath string) {
+	return filepath.Join(sm.dir, name+".vert"), filepath.Join(sm.dir, name+".frag")
+}
+
+func (sm *ShaderManager) readSources(name string) (vertSrc, fragSrc []byte, err error) {
+	vertPath, fragPath := sm.sourcePaths(name)
+
+	vertSrc, err = os.ReadFile(vertPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shader manager: read %s: %w", vertPath, err)
+	}
+	fragSrc, err = os.ReadFile(fragPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shader manager: read %s: %w", fragPath, err)
+	}
+	return vertSrc, fragSrc, nil
+}
+
+func sourceHash(vertSrc, fragSrc []byte) string {
+	h := sha256.New()
+	h.Write(vertSrc)
+	h.Write(fragSrc)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// linkProgram compiles and links a vertex/fragment pair, returning compile
+// and link failures as errors instead of printing them to stdout, so callers
+// can decide whether a failed reload should replace the live program.
+func linkProgram(vertexSource, fragmentSource string) (uint32, error) {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		gl.DeleteShader(vertexShader)
+		return 0, err
+	}
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+	gl.LinkProgram(program)
+	checkGLErrorIfDebug("shader manager link program")
+
+	gl.DeleteShader(vertexShader)
+	gl.DeleteShader(fragmentShader)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+		infoLog := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(infoLog))
+		gl.DeleteProgram(program)
+		return 0, fmt.Errorf("link failed: %s", infoLog)
+	}
+
+	return program, nil
+}
+
+// compileShader compiles source as a shader of shaderType, returning the
+// compile log as an error on failure.
+func compileShader(source string, shaderType uint32) (uint32, error) {
+	shader := gl.CreateShader(shaderType)
+	csources, free := gl.Strs(source)
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+	checkGLErrorIfDebug("shader manager compile shader")
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+		infoLog := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(infoLog))
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("compile failed: %s", infoLog)
+	}
+
+	return shader, nil
+}
+
+// fetchActiveUniforms pre-fetches every active uniform's location right
+// after linking so per-frame lookups become Program.Uniform map reads.
+func fetchActiveUniforms(program uint32) map[string]int32 {
+	var count int32
+	gl.GetProgramiv(program, gl.ACTIVE_UNIFORMS, &count)
+
+	uniforms := make(map[string]int32, count)
+	nameBuf := make([]byte, 256)
+	for i := uint32(0); i < uint32(count); i++ {
+		var length, size int32
+		var xtype uint32
+		gl.GetActiveUniform(program, i, int32(len(nameBuf)), &length, &size, &xtype, &nameBuf[0])
+		name := string(nameBuf[:length])
+		uniforms[name] = gl.GetUniformLocation(program, gl.Str(name+"\x00"))
+	}
+	return uniforms
+}
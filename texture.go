@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// TextureFormat identifies the pixel layout of a loaded texture.
+type TextureFormat int
+
+const (
+	FormatRGBA8 TextureFormat = iota
+	FormatDXT1
+	FormatDXT3
+	FormatDXT5
+)
+
+// Texture is the result of loading an image file onto the GPU.
+type Texture struct {
+	ID     uint32
+	Width  int32
+	Height int32
+	Format TextureFormat
+}
+
+// TextureOptions controls how a loaded image is uploaded and sampled.
+type TextureOptions struct {
+	WrapS, WrapT         int32
+	MinFilter, MagFilter int32
+	Anisotropy           float32 // 0 disables anisotropic filtering
+	SRGB                 bool
+	GenerateMipmaps      bool
+}
+
+// DefaultTextureOptions matches the wrap/filter settings loadTexture used to
+// hardcode: repeat wrapping, linear filtering, no mipmaps.
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{
+		WrapS:     gl.REPEAT,
+		WrapT:     gl.REPEAT,
+		MinFilter: gl.LINEAR,
+		MagFilter: gl.LINEAR,
+	}
+}
+
+// LoadTexture sniffs filename's extension to dispatch to a PNG, JPEG, or DDS
+// decoder, uploads the result to a new GL texture object, and applies opts.
+func LoadTexture(filename string, opts TextureOptions) (*Texture, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("load texture %q: %w", filename, err)
+	}
+	defer file.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".dds":
+		return loadDDS(file, opts)
+	case ".png":
+		img, err := png.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("load texture %q: decode png: %w", filename, err)
+		}
+		return uploadImage(img, opts)
+	case ".jpg", ".jpeg":
+		img, err := jpeg.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("load texture %q: decode jpeg: %w", filename, err)
+		}
+		return uploadImage(img, opts)
+	default:
+		return nil, fmt.Errorf("load texture %q: unsupported extension %q", filename, ext)
+	}
+}
+
+// uploadImage uploads an already-decoded image as an uncompressed RGBA8
+// texture and applies opts.
+func uploadImage(img image.Image, opts TextureOptions) (*Texture, error) {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(bounds)
+	draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
+
+	width, height := int32(bounds.Dx()), int32(bounds.Dy())
+
+	internalFormat := int32(gl.RGBA)
+	if opts.SRGB {
+		internalFormat = gl.SRGB_ALPHA
+	}
+
+	var id uint32
+	gl.GenTextures(1, &id)
+	gl.BindTexture(gl.TEXTURE_2D, id)
+
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		internalFormat,
+		width,
+		height,
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(rgba.Pix))
+	checkGLErrorIfDebug("upload rgba texture")
+
+	applyTextureParams(opts)
+	if opts.GenerateMipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	return &Texture{ID: id, Width: width, Height: height, Format: FormatRGBA8}, nil
+}
+
+// applyTextureParams sets wrap/filter/anisotropy state on the texture
+// currently bound to GL_TEXTURE_2D.
+func applyTextureParams(opts TextureOptions) {
+	minFilter := opts.MinFilter
+	if opts.GenerateMipmaps {
+		minFilter = gl.LINEAR_MIPMAP_LINEAR
+	}
+
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, opts.WrapS)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, opts.WrapT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, opts.MagFilter)
+
+	if opts.Anisotropy > 0 {
+		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY, opts.Anisotropy)
+	}
+}
+
+// ddsHeader mirrors the 124-byte DDS_HEADER struct (following the 4-byte
+// "DDS " magic), little-endian, as documented by the DDS file format.
+type ddsHeader struct {
+	Size              uint32
+	Flags             uint32
+	Height            uint32
+	Width             uint32
+	PitchOrLinearSize uint32
+	Depth             uint32
+	MipMapCount       uint32
+	Reserved1         [11]uint32
+	PFSize            uint32
+	PFFlags           uint32
+	PFFourCC          [4]byte
+	PFRGBBitCount     uint32
+	PFRBitMask        uint32
+	PFGBitMask        uint32
+	PFBBitMask        uint32
+	PFABitMask        uint32
+	Caps              uint32
+	Caps2             uint32
+	Caps3             uint32
+	Caps4             uint32
+	Reserved2         uint32
+}
+
+// loadDDS parses a DXT1/DXT3/DXT5-compressed DDS file and uploads its mip
+// chain via gl.CompressedTexImage2D.
+func loadDDS(file *os.File, opts TextureOptions) (*Texture, error) {
+	var magic [4]byte
+	if _, err := file.Read(magic[:]); err != nil {
+		return nil, fmt.Errorf("load dds: read magic: %w", err)
+	}
+	if string(magic[:]) != "DDS " {
+		return nil, fmt.Errorf("load dds: bad magic %q", magic)
+	}
+
+	var hdr ddsHeader
+	if err := binary.Read(file, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("load dds: read header: %w", err)
+	}
+
+	var internalFormat uint32
+	var format TextureFormat
+	var blockSize int
+	switch string(hdr.PFFourCC[:]) {
+	case "DXT1":
+		internalFormat = gl.COMPRESSED_RGBA_S3TC_DXT1_EXT
+		format = FormatDXT1
+		blockSize = 8
+	case "DXT3":
+		internalFormat = gl.COMPRESSED_RGBA_S3TC_DXT3_EXT
+		format = FormatDXT3
+		blockSize = 16
+	case "DXT5":
+		internalFormat = gl.COMPRESSED_RGBA_S3TC_DXT5_EXT
+		format = FormatDXT5
+		blockSize = 16
+	default:
+		return nil, fmt.Errorf("load dds: unsupported fourCC %q", hdr.PFFourCC)
+	}
+
+	data, err := func() ([]byte, error) {
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(file); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("load dds: read data: %w", err)
+	}
+
+	mipCount := int(hdr.MipMapCount)
+	if mipCount == 0 {
+		mipCount = 1
+	}
+
+	var id uint32
+	gl.GenTextures(1, &id)
+	gl.BindTexture(gl.TEXTURE_2D, id)
+
+	width, height := int32(hdr.Width), int32(hdr.Height)
+	offset := 0
+	for level := 0; level < mipCount && (width > 0 || height > 0); level++ {
+		w, h := width, height
+		if w < 1 {
+			w = 1
+		}
+		if h < 1 {
+			h = 1
+		}
+
+		size := ((int(w) + 3) / 4) * ((int(h) + 3) / 4) * blockSize
+		if offset+size > len(data) {
+			return nil, fmt.Errorf("load dds: mip level %d overruns file data", level)
+		}
+
+		gl.CompressedTexImage2D(
+			gl.TEXTURE_2D,
+			int32(level),
+			internalFormat,
+			w,
+			h,
+			0,
+			int32(size),
+			gl.Ptr(data[offset:offset+size]))
+		checkGLErrorIfDebug("upload dds mip level")
+
+		offset += size
+		width /= 2
+		height /= 2
+	}
+
+	// Block-compressed formats can't be mipmapped by gl.GenerateMipmap: it's
+	// unsupported/undefined on most GL 4.1 drivers for compressed internal
+	// formats, even with a single baked-in mip. loadDDS only ever produces
+	// DXT1/3/5 textures, so this is never FormatRGBA8, but the check is kept
+	// explicit rather than relying on that.
+	opts.GenerateMipmaps = opts.GenerateMipmaps && mipCount == 1 && format == FormatRGBA8
+	applyTextureParams(opts)
+	if opts.GenerateMipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	return &Texture{ID: id, Width: int32(hdr.Width), Height: int32(hdr.Height), Format: format}, nil
+}
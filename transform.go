@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ErrTransformStackEmpty is returned by Pop when only the base identity
+// element remains on the stack.
+var ErrTransformStackEmpty = errors.New("transform stack: cannot pop base identity")
+
+// TransformStack accumulates nested model transforms so scene-graph style
+// hierarchies (turrets on tanks, limbs on characters, grid cells, ...) can be
+// built without manual matrix bookkeeping. The stack always holds at least
+// one element, the identity matrix, which anchors world space and cannot be
+// popped.
+type TransformStack struct {
+	stack []mgl32.Mat4
+}
+
+// NewTransformStack returns a stack seeded with the identity matrix.
+func NewTransformStack() *TransformStack {
+	return &TransformStack{stack: []mgl32.Mat4{mgl32.Ident4()}}
+}
+
+// Push multiplies m into the current top of the stack and pushes the result,
+// so Peek always returns the accumulated world transform.
+func (t *TransformStack) Push(m mgl32.Mat4) {
+	t.stack = append(t.stack, t.Peek().Mul4(m))
+}
+
+// Pop removes and returns the top of the stack. It errors if only the base
+// identity element remains, leaving the stack unchanged.
+func (t *TransformStack) Pop() (mgl32.Mat4, error) {
+	if len(t.stack) <= 1 {
+		return mgl32.Mat4{}, ErrTransformStackEmpty
+	}
+
+	top := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	return top, nil
+}
+
+// Peek returns the accumulated transform at the top of the stack without
+// removing it.
+func (t *TransformStack) Peek() mgl32.Mat4 {
+	return t.stack[len(t.stack)-1]
+}
+
+// Len returns the number of elements currently on the stack, including the
+// base identity.
+func (t *TransformStack) Len() int {
+	return len(t.stack)
+}
+
+// Copy deep-copies the stack so a child stack can diverge from its parent
+// without aliasing the parent's underlying slice.
+func (t *TransformStack) Copy() *TransformStack {
+	cp := make([]mgl32.Mat4, len(t.stack))
+	copy(cp, t.stack)
+	return &TransformStack{stack: cp}
+}
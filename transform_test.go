@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestTransformStackPushMultipliesIntoParent(t *testing.T) {
+	ts := NewTransformStack()
+
+	translate := mgl32.Translate3D(1, 2, 3)
+	ts.Push(translate)
+	if got, want := ts.Peek(), translate; got != want {
+		t.Fatalf("after pushing onto identity, Peek() = %v, want %v", got, want)
+	}
+
+	scale := mgl32.Scale3D(2, 2, 2)
+	ts.Push(scale)
+	if got, want := ts.Peek(), translate.Mul4(scale); got != want {
+		t.Fatalf("Peek() = %v, want translate.Mul4(scale) = %v", got, want)
+	}
+}
+
+func TestTransformStackPopReturnsPushedValue(t *testing.T) {
+	ts := NewTransformStack()
+	ts.Push(mgl32.Translate3D(1, 0, 0))
+	want := ts.Peek()
+
+	got, err := ts.Pop()
+	if err != nil {
+		t.Fatalf("Pop() returned unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Pop() = %v, want %v", got, want)
+	}
+	if ts.Len() != 1 {
+		t.Fatalf("Len() after popping back to base = %d, want 1", ts.Len())
+	}
+}
+
+func TestTransformStackPopErrorsAtBase(t *testing.T) {
+	ts := NewTransformStack()
+
+	if _, err := ts.Pop(); err != ErrTransformStackEmpty {
+		t.Fatalf("Pop() on a fresh stack returned %v, want ErrTransformStackEmpty", err)
+	}
+	if ts.Len() != 1 {
+		t.Fatalf("Len() after a failed Pop() = %d, want 1 (stack left unchanged)", ts.Len())
+	}
+
+	ts.Push(mgl32.Ident4())
+	if _, err := ts.Pop(); err != nil {
+		t.Fatalf("Pop() on a two-element stack returned unexpected error: %v", err)
+	}
+	if _, err := ts.Pop(); err != ErrTransformStackEmpty {
+		t.Fatalf("Pop() back at the base returned %v, want ErrTransformStackEmpty", err)
+	}
+}
+
+func TestTransformStackCopyDoesNotAliasParent(t *testing.T) {
+	parent := NewTransformStack()
+	parent.Push(mgl32.Translate3D(1, 0, 0))
+
+	child := parent.Copy()
+	child.Push(mgl32.Translate3D(0, 1, 0))
+
+	if parent.Len() != 2 {
+		t.Fatalf("parent.Len() after child diverged = %d, want 2 (parent untouched)", parent.Len())
+	}
+	if child.Len() != 3 {
+		t.Fatalf("child.Len() = %d, want 3", child.Len())
+	}
+	if parent.Peek() == child.Peek() {
+		t.Fatalf("parent and child converged to the same transform after diverging")
+	}
+}